@@ -0,0 +1,179 @@
+package operator
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/automata-network/multi-prover-avs/utils"
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// QuoteProducer abstracts the mechanism used to produce a TEE attestation
+// quote for a given pubkey, so attestationRenewer can drive any TEE-backed
+// prover against the same TEELivenessVerifier without the renewal loop
+// itself knowing which hardware backs it.
+type QuoteProducer interface {
+	GenerateQuote(ctx context.Context, pubkey []byte) ([]byte, error)
+}
+
+// sgxQuoteProducer is the existing Intel SGX path: proverClient.GenerateAttestaionReport,
+// or a simulated quote in Config.Simulation mode.
+type sgxQuoteProducer struct {
+	operator *Operator
+}
+
+func (p *sgxQuoteProducer) GenerateQuote(ctx context.Context, pubkey []byte) ([]byte, error) {
+	return p.operator.proverGetAttestationReport(ctx, pubkey)
+}
+
+// tdxQuoteProducer and sevSnpQuoteProducer are placeholders selectable via
+// Config.TEEKind ("tdx", "sev-snp"). Their GenerateQuote always returns an
+// error: neither calls a prover client to produce a quote.
+type tdxQuoteProducer struct {
+	operator *Operator
+}
+
+func (p *tdxQuoteProducer) GenerateQuote(ctx context.Context, pubkey []byte) ([]byte, error) {
+	return nil, logex.NewErrorf("tdx attestation is not yet supported")
+}
+
+type sevSnpQuoteProducer struct {
+	operator *Operator
+}
+
+func (p *sevSnpQuoteProducer) GenerateQuote(ctx context.Context, pubkey []byte) ([]byte, error) {
+	return nil, logex.NewErrorf("sev-snp attestation is not yet supported")
+}
+
+func newQuoteProducer(kind string, operator *Operator) (QuoteProducer, error) {
+	switch kind {
+	case "", "sgx":
+		return &sgxQuoteProducer{operator: operator}, nil
+	case "tdx":
+		return &tdxQuoteProducer{operator: operator}, nil
+	case "sev-snp":
+		return &sevSnpQuoteProducer{operator: operator}, nil
+	default:
+		return nil, logex.NewErrorf("unknown tee kind: %v", kind)
+	}
+}
+
+const (
+	attestationMinBackoff = time.Second
+	attestationMaxBackoff = 5 * time.Minute
+	// attestationRenewMargin is how long before the on-chain attestation
+	// expires that the renewer submits a fresh proof.
+	attestationRenewMargin = 300 * time.Second
+	// attestationPollFallback is how long the renewer waits after a
+	// successful renewal (whose tx renew has already waited to be mined)
+	// before re-reading the new deadline.
+	attestationPollFallback = 30 * time.Second
+)
+
+// attestationRenewer keeps an operator's TEE liveness proof from expiring.
+// Unlike the loop it replaces, it honors ctx so Operator.Start can shut it
+// down cleanly, backs off with jitter on RPC failures instead of spinning,
+// and re-reads AttestValiditySeconds on every iteration in case governance
+// changes it mid-flight.
+type attestationRenewer struct {
+	operator      *Operator
+	quoteProducer QuoteProducer
+	pubkeyBytes   []byte
+}
+
+func newAttestationRenewer(operator *Operator, quoteProducer QuoteProducer, pubkeyBytes []byte) *attestationRenewer {
+	return &attestationRenewer{
+		operator:      operator,
+		quoteProducer: quoteProducer,
+		pubkeyBytes:   pubkeyBytes,
+	}
+}
+
+// Run blocks until ctx is cancelled, periodically renewing the attestation
+// report as its deadline approaches.
+func (r *attestationRenewer) Run(ctx context.Context) {
+	backoff := attestationMinBackoff
+	for {
+		wait, err := r.checkNext(ctx)
+		if err != nil {
+			logex.Error(err)
+			r.operator.metrics.IncAttestationRenewalFailure()
+
+			wait = backoff + time.Duration(rand.Int63n(int64(backoff)+1))
+			backoff *= 2
+			if backoff > attestationMaxBackoff {
+				backoff = attestationMaxBackoff
+			}
+		} else {
+			backoff = attestationMinBackoff
+			r.operator.metrics.IncAttestationRenewalSuccess()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkNext reads the current attestation deadline, renews it if it's
+// within attestationRenewMargin, and returns how long to wait before
+// checking again.
+func (r *attestationRenewer) checkNext(ctx context.Context) (time.Duration, error) {
+	validSecs, err := r.operator.TEELivenessVerifier.AttestValiditySeconds(nil)
+	if err != nil {
+		return 0, logex.Trace(err)
+	}
+	key := crypto.Keccak256Hash(r.pubkeyBytes)
+	prover, err := r.operator.TEELivenessVerifier.AttestedProvers(nil, key)
+	if err != nil {
+		return 0, logex.Trace(err)
+	}
+
+	deadline := prover.Time.Int64() + validSecs.Int64()
+	r.operator.metrics.SetNextAttestationDeadline(deadline)
+	logex.Info("next attestation will be at", time.Unix(deadline, 0))
+
+	now := time.Now()
+	renewAt := time.Unix(deadline, 0).Add(-attestationRenewMargin)
+	if now.Before(renewAt) {
+		return renewAt.Sub(now), nil
+	}
+
+	if err := r.renew(ctx); err != nil {
+		return 0, logex.Trace(err)
+	}
+	return attestationPollFallback, nil
+}
+
+// renew fetches a fresh quote from the renewer's QuoteProducer and submits
+// it to the TEELivenessVerifier.
+func (r *attestationRenewer) renew(ctx context.Context) error {
+	report, err := r.quoteProducer.GenerateQuote(ctx, r.pubkeyBytes)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	chainId, err := r.operator.cfg.AttestationClient.ChainID(ctx)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	opt, err := bind.NewKeyedTransactorWithChainID(r.operator.cfg.AttestationEcdsaKey, chainId)
+	if err != nil {
+		return logex.Trace(err)
+	}
+
+	tx, err := r.operator.TEELivenessVerifier.SubmitLivenessProof(opt, report)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	logex.Infof("submitted liveness proof: %v", tx.Hash())
+	if _, err := utils.WaitTx(ctx, r.operator.cfg.AttestationClient, tx, nil); err != nil {
+		return logex.Trace(err)
+	}
+	logex.Infof("registered in TEELivenessVerifier: %v", tx.Hash())
+	return nil
+}
@@ -0,0 +1,52 @@
+package operator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// These register against promauto's implicit prometheus.DefaultRegisterer
+// rather than anything hung off *Metrics deliberately: Metrics.Start serves
+// the operator's metrics endpoint over the default registry already (the
+// same convention eigensdk-go's own operator/avs counters use), so any
+// subsystem in this binary can contribute a counter via promauto without
+// needing a handle to the *Metrics value. The *Metrics methods below are
+// thin wrappers over them.
+var (
+	attestationRenewalSuccessTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "multi_prover_avs",
+		Subsystem: "attestation",
+		Name:      "renewal_success_total",
+		Help:      "Number of TEE attestation reports successfully renewed.",
+	})
+	attestationRenewalFailureTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "multi_prover_avs",
+		Subsystem: "attestation",
+		Name:      "renewal_failure_total",
+		Help:      "Number of failed TEE attestation renewal attempts.",
+	})
+	attestationNextDeadline = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "multi_prover_avs",
+		Subsystem: "attestation",
+		Name:      "next_renewal_deadline_unix",
+		Help:      "Unix timestamp at which the current TEE attestation report expires.",
+	})
+)
+
+// IncAttestationRenewalSuccess records that attestationRenewer successfully
+// submitted a fresh liveness proof.
+func (m *Metrics) IncAttestationRenewalSuccess() {
+	attestationRenewalSuccessTotal.Inc()
+}
+
+// IncAttestationRenewalFailure records that attestationRenewer failed to
+// read the current attestation deadline or submit a renewed proof.
+func (m *Metrics) IncAttestationRenewalFailure() {
+	attestationRenewalFailureTotal.Inc()
+}
+
+// SetNextAttestationDeadline records the unix timestamp at which the
+// operator's current TEE attestation report expires.
+func (m *Metrics) SetNextAttestationDeadline(deadline int64) {
+	attestationNextDeadline.Set(float64(deadline))
+}
@@ -1,28 +1,21 @@
 package operator
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
-	"io"
 	"math/big"
 	"os"
 	"strconv"
-	"time"
 
 	sdkTypes "github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/automata-network/multi-prover-avs/aggregator"
 	"github.com/automata-network/multi-prover-avs/contracts/bindings/TEELivenessVerifier"
 	"github.com/automata-network/multi-prover-avs/utils"
 	"github.com/chzyer/logex"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -39,8 +32,7 @@ type Operator struct {
 	quorumNumbers []byte
 
 	proverClient *ProverClient
-	taskFetcher  *LogTracer
-	offset       *os.File
+	fetchers     []*rollupFetcher
 
 	TEELivenessVerifier *TEELivenessVerifier.TEELivenessVerifier
 }
@@ -77,86 +69,80 @@ func NewOperator(path string) (*Operator, error) {
 		return nil, logex.NewErrorf("operator is not registered")
 	}
 
-	quorumNames := map[sdkTypes.QuorumNum]string{
-		0: "Scroll SGX Quorum",
-	}
-	quorumNumbers := []byte{0}
-
-	metrics := NewMetrics(cfg.EigenClients, utils.NewLogger(logger), operatorAddress, cfg.Config.EigenMetricsIpPortAddress, quorumNames)
-
 	operator := &Operator{
 		cfg:                 cfg,
 		proverClient:        proverClient,
 		logger:              logger,
-		quorumNumbers:       quorumNumbers,
 		aggregator:          aggClient,
 		operatorAddress:     operatorAddress,
-		metrics:             metrics,
 		TEELivenessVerifier: TEELivenessVerifier,
 	}
 
-	if cfg.Config.TaskFetcher != nil {
-		taskFetcherClient, err := ethclient.Dial(cfg.Config.TaskFetcher.Endpoint)
+	quorumNames := map[sdkTypes.QuorumNum]string{}
+	for i, entry := range cfg.Config.TaskFetchers {
+		adapter, err := buildRollupAdapter(entry)
+		if err != nil {
+			return nil, logex.Trace(err, "taskFetchers["+strconv.Itoa(i)+"]")
+		}
+
+		fetcherClient, err := ethclient.Dial(entry.Endpoint)
 		if err != nil {
 			return nil, logex.Trace(err)
 		}
 
-		offsetFile, err := os.OpenFile(cfg.Config.TaskFetcher.OffsetFile, os.O_CREATE|os.O_RDWR, 0644)
+		offsetFile, err := os.OpenFile(entry.OffsetFile, os.O_CREATE|os.O_RDWR, 0644)
 		if err != nil {
 			return nil, logex.Trace(err)
 		}
 
-		operator.offset = offsetFile
-		operator.taskFetcher = NewLogTracer(taskFetcherClient, &LogTracerConfig{
-			Id:               "operator-log-tracer",
+		fetcher := &rollupFetcher{
+			operator: operator,
+			adapter:  adapter,
+			source:   fetcherClient,
+			offset:   offsetFile,
+		}
+		fetcher.tracer = NewLogTracer(fetcherClient, &LogTracerConfig{
+			Id:               "operator-log-tracer-" + adapter.QuorumName(),
 			Wait:             5,
 			Max:              100,
-			ScanIntervalSecs: cfg.Config.TaskFetcher.ScanIntervalSecs,
-			Topics:           cfg.Config.TaskFetcher.Topics,
-			Addresses:        cfg.Config.TaskFetcher.Addresses,
-			Handler:          operator,
+			ScanIntervalSecs: entry.ScanIntervalSecs,
+			Topics:           adapter.Topics(),
+			Addresses:        adapter.Addresses(),
+			Handler:          fetcher,
 			SkipOnError:      true,
 		})
-	}
 
-	return operator, nil
-}
-
-// callback func for task fetcher
-func (h *Operator) GetBlock() (uint64, error) {
-	data := make([]byte, 16)
-	n, err := h.offset.ReadAt(data, 0)
-	if n == 0 {
-		if err == io.EOF {
-			return 0, nil
+		workers := cfg.Config.MaxConcurrentTasks
+		if workers <= 0 {
+			workers = defaultMaxConcurrentTasks
 		}
-		return 0, logex.Trace(err, n)
-	}
-	data = bytes.Trim(data[:n], "\x00\r\n ")
+		fetcher.pool = newTaskPool(workers, fetcher.processLog, fetcher.persistOffset, true, logger)
 
-	number, err := strconv.ParseInt(string(data), 10, 64)
-	if err != nil {
-		return 0, logex.Trace(err)
+		quorumNames[sdkTypes.QuorumNum(adapter.QuorumNumber())] = adapter.QuorumName()
+		operator.quorumNumbers = append(operator.quorumNumbers, adapter.QuorumNumber())
+		operator.fetchers = append(operator.fetchers, fetcher)
 	}
-	return uint64(number), nil
-}
 
-// callback func for task fetcher
-func (h *Operator) SaveBlock(offset uint64) error {
-	data := []byte(strconv.FormatUint(offset, 10))
-	_, err := h.offset.WriteAt(data, 0)
-	return err
+	operator.metrics = NewMetrics(cfg.EigenClients, utils.NewLogger(logger), operatorAddress, cfg.Config.EigenMetricsIpPortAddress, quorumNames)
+
+	return operator, nil
 }
 
-// callback func for task fetcher
-func (o *Operator) OnNewLog(ctx context.Context, log *types.Log) error {
+// defaultMaxConcurrentTasks bounds the worker pool used when
+// Config.MaxConcurrentTasks is unset.
+const defaultMaxConcurrentTasks = 4
+
+// processLog runs the actual fetch -> sign -> submit pipeline for a single
+// log produced by fetcher's adapter. It is invoked concurrently by that
+// fetcher's worker pool goroutines, so it must not mutate shared state.
+func (o *Operator) processLog(ctx context.Context, fetcher *rollupFetcher, log *types.Log) error {
 	blockHeader, err := o.cfg.Client.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return logex.Trace(err)
 	}
 
 	// parse the task
-	poe, skip, err := o.proverGetPoe(ctx, log.TxHash, log.Topics)
+	poe, skip, err := o.proverGetPoe(ctx, fetcher, log.TxHash, log.Topics)
 	if err != nil {
 		return logex.Trace(err)
 	}
@@ -175,10 +161,10 @@ func (o *Operator) OnNewLog(ctx context.Context, log *types.Log) error {
 	}
 
 	stateHeader := &aggregator.StateHeader{
-		Identifier:                 (*hexutil.Big)(big.NewInt(o.cfg.Config.Identifier)),
+		Identifier:                 (*hexutil.Big)(big.NewInt(fetcher.adapter.Identifier())),
 		Metadata:                   mdBytes,
 		State:                      poe.Poe.Pack(),
-		QuorumNumbers:              o.quorumNumbers,
+		QuorumNumbers:              []byte{fetcher.adapter.QuorumNumber()},
 		QuorumThresholdPercentages: []byte{0},
 		ReferenceBlockNumber:       uint32(blockHeader.Number.Int64() - 1),
 	}
@@ -231,8 +217,31 @@ func (o *Operator) Start(ctx context.Context) error {
 		o.cfg.BlsKey.GetPubKeyG2(),
 	)
 
-	if err := o.taskFetcher.Run(ctx); err != nil {
-		return logex.Trace(err)
+	workers := o.cfg.Config.MaxConcurrentTasks
+	if workers <= 0 {
+		workers = defaultMaxConcurrentTasks
+	}
+
+	errCh := make(chan error, len(o.fetchers)*2)
+	for _, fetcher := range o.fetchers {
+		poolErrCh := fetcher.Start(ctx, workers)
+		go func(poolErrCh <-chan error) {
+			if err := <-poolErrCh; err != nil {
+				errCh <- err
+			}
+		}(poolErrCh)
+		go func(fetcher *rollupFetcher) {
+			errCh <- fetcher.tracer.Run(ctx)
+		}(fetcher)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return logex.Trace(err)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 
 	return nil
@@ -253,44 +262,25 @@ func (o *Operator) checkIsRegistered() error {
 	return nil
 }
 
-var ABI = func() abi.ABI {
-	ty := `[{"inputs":[{"internalType":"uint8","name":"_version","type":"uint8"},{"internalType":"bytes","name":"_parentBatchHeader","type":"bytes"},{"internalType":"bytes[]","name":"_chunks","type":"bytes[]"},{"internalType":"bytes","name":"_skippedL1MessageBitmap","type":"bytes"}],"name":"commitBatch","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
-	result, err := abi.JSON(bytes.NewReader([]byte(ty)))
-	if err != nil {
-		panic(err)
-	}
-	return result
-}()
-
-func (o *Operator) proverGetPoe(ctx context.Context, txHash common.Hash, topics []common.Hash) (*PoeResponse, bool, error) {
+func (o *Operator) proverGetPoe(ctx context.Context, fetcher *rollupFetcher, txHash common.Hash, topics []common.Hash) (*PoeResponse, bool, error) {
 	if o.cfg.Config.Simulation {
-		tx, _, err := o.taskFetcher.source.TransactionByHash(ctx, txHash)
+		tx, _, err := fetcher.source.TransactionByHash(ctx, txHash)
 		if err != nil {
 			return nil, false, logex.Trace(err)
 		}
-		args, err := ABI.Methods["commitBatch"].Inputs.Unpack(tx.Data()[4:])
+		refs, meta, err := fetcher.adapter.DecodeBatch(tx, topics)
 		if err != nil {
 			return nil, false, logex.Trace(err)
 		}
-
-		startBlock := int64(0)
-		endBlock := int64(0)
-		for _, chunk := range args[2].([][]byte) {
-			for i := 0; i < int(chunk[0]); i++ {
-				blockNumber := int64(binary.BigEndian.Uint64(chunk[1:][i*60 : i*60+8]))
-				if startBlock == 0 {
-					startBlock = blockNumber
-				} else {
-					endBlock = blockNumber
-				}
-			}
+		if len(refs) == 0 {
+			return nil, true, nil
 		}
 
-		startBlockHeader, err := o.taskFetcher.source.HeaderByNumber(ctx, big.NewInt(startBlock))
+		startBlockHeader, err := fetcher.source.HeaderByNumber(ctx, big.NewInt(int64(meta.StartBlock)))
 		if err != nil {
 			return nil, false, logex.Trace(err)
 		}
-		endBlockHeader, err := o.taskFetcher.source.HeaderByNumber(ctx, big.NewInt(endBlock))
+		endBlockHeader, err := fetcher.source.HeaderByNumber(ctx, big.NewInt(int64(meta.EndBlock)))
 		if err != nil {
 			return nil, false, logex.Trace(err)
 		}
@@ -301,8 +291,9 @@ func (o *Operator) proverGetPoe(ctx context.Context, txHash common.Hash, topics
 				NewStateRoot:  endBlockHeader.Root,
 				PrevStateRoot: startBlockHeader.Root,
 			},
-			StartBlock: uint64(startBlock),
-			EndBlock:   uint64(endBlock),
+			BatchId:    meta.BatchId,
+			StartBlock: meta.StartBlock,
+			EndBlock:   meta.EndBlock,
 		}
 		return response, false, nil
 	}
@@ -330,32 +321,10 @@ func (o *Operator) proverGetAttestationReport(ctx context.Context, pubkey []byte
 	return quote, nil
 }
 
-func (o *Operator) registerAttestationReport(ctx context.Context, pubkeyBytes []byte) error {
-	report, err := o.proverGetAttestationReport(ctx, pubkeyBytes)
-	if err != nil {
-		return logex.Trace(err)
-	}
-	chainId, err := o.cfg.AttestationClient.ChainID(ctx)
-	if err != nil {
-		return logex.Trace(err)
-	}
-	opt, err := bind.NewKeyedTransactorWithChainID(o.cfg.AttestationEcdsaKey, chainId)
-	if err != nil {
-		return logex.Trace(err)
-	}
-
-	tx, err := o.TEELivenessVerifier.SubmitLivenessProof(opt, report)
-	if err != nil {
-		return logex.Trace(err)
-	}
-	logex.Infof("submitted liveness proof: %v", tx.Hash())
-	if _, err := utils.WaitTx(ctx, o.cfg.AttestationClient, tx, nil); err != nil {
-		return logex.Trace(err)
-	}
-	logex.Infof("registered in TEELivenessVerifier: %v", tx.Hash())
-	return nil
-}
-
+// RegisterAttestationReport checks whether the operator already holds a
+// valid TEE liveness proof, submits one if not, then hands the proof off to
+// an attestationRenewer goroutine bound to ctx so it keeps the proof fresh
+// for as long as the operator runs.
 func (o *Operator) RegisterAttestationReport(ctx context.Context) error {
 	logex.Info("checking tee liveness...")
 	pubkeyBytes := o.cfg.BlsKey.PubKey.Serialize()
@@ -363,6 +332,12 @@ func (o *Operator) RegisterAttestationReport(ctx context.Context) error {
 		return logex.NewErrorf("invalid pubkey")
 	}
 
+	quoteProducer, err := newQuoteProducer(o.cfg.Config.TEEKind, o)
+	if err != nil {
+		return logex.Trace(err)
+	}
+	renewer := newAttestationRenewer(o, quoteProducer, pubkeyBytes)
+
 	var x, y [32]byte
 	copy(x[:], pubkeyBytes[:32])
 	copy(y[:], pubkeyBytes[32:64])
@@ -372,38 +347,11 @@ func (o *Operator) RegisterAttestationReport(ctx context.Context) error {
 	}
 	if isRegistered {
 		logex.Info("Operater has registered on TEE Liveness Verifier")
-	} else {
-		if err := o.registerAttestationReport(ctx, pubkeyBytes); err != nil {
-			return logex.Trace(err)
-		}
+	} else if err := renewer.renew(ctx); err != nil {
+		return logex.Trace(err)
 	}
 
-	checkNext := func(ctx context.Context) error {
-		validSecs, err := o.TEELivenessVerifier.AttestValiditySeconds(nil)
-		if err != nil {
-			return logex.Trace(err)
-		}
-		key := crypto.Keccak256Hash(pubkeyBytes)
-		prover, err := o.TEELivenessVerifier.AttestedProvers(nil, key)
-		if err != nil {
-			return logex.Trace(err)
-		}
-		deadline := prover.Time.Int64() + validSecs.Int64()
-		now := time.Now().Unix()
-		logex.Info("next attestation will be at", time.Unix(deadline, 0))
-		if deadline > now+300 {
-			time.Sleep(time.Duration(deadline-now-300) * time.Second)
-		}
-		return o.registerAttestationReport(ctx, pubkeyBytes)
-	}
-	go func() {
-		ctx := context.Background()
-		for {
-			if err := checkNext(ctx); err != nil {
-				logex.Error(err)
-			}
-		}
-	}()
+	go renewer.Run(ctx)
 
 	return nil
 }
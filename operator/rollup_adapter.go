@@ -0,0 +1,81 @@
+package operator
+
+import (
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BlockRef identifies a single L2 block covered by a batch, in the order the
+// rollup's batch-submission transaction lists it.
+type BlockRef struct {
+	Number uint64
+}
+
+// BatchMeta carries the rollup-specific identifiers the aggregator needs to
+// correlate a signed task back to the batch it covers.
+type BatchMeta struct {
+	BatchId    uint64
+	StartBlock uint64
+	EndBlock   uint64
+}
+
+// RollupAdapter decodes one rollup stack's batch-submission transactions and
+// supplies the log filter + AVS identity NewOperator needs to run a
+// LogTracer against it. Implementations must be stateless and safe for
+// concurrent use, since DecodeBatch is called from every worker in the
+// fetcher's task pool.
+type RollupAdapter interface {
+	// Identifier is the on-chain AVS identifier tasks decoded by this
+	// adapter are submitted under.
+	Identifier() int64
+	// QuorumName is the human readable quorum label registered with the
+	// eigenlayer metrics server.
+	QuorumName() string
+	// QuorumNumber is the on-chain quorum number tasks decoded by this
+	// adapter must be signed and submitted under. It comes from the
+	// adapter's config entry, not from that entry's position in
+	// Config.TaskFetchers, so reordering the config can't silently remap
+	// which quorum a rollup's tasks are submitted under.
+	QuorumNumber() byte
+	// Topics are the log topics a LogTracer should filter on to find this
+	// rollup's batch-submission events.
+	Topics() []common.Hash
+	// Addresses restricts the LogTracer filter to this rollup's contracts.
+	Addresses() []common.Address
+	// DecodeBatch extracts the L2 block range and batch metadata committed
+	// by tx. topics are the topics of the log that triggered processing;
+	// the Scroll and Optimism adapters in this package do not read them.
+	DecodeBatch(tx *types.Transaction, topics []common.Hash) ([]BlockRef, BatchMeta, error)
+}
+
+// RollupAdapterFactory builds a RollupAdapter from one TaskFetcher config
+// entry. Adapters register themselves under a config-level kind name via
+// RegisterRollupAdapter so NewOperator can select them by string.
+type RollupAdapterFactory func(entry *TaskFetcherConfig) (RollupAdapter, error)
+
+var rollupAdapterFactories = map[string]RollupAdapterFactory{}
+
+// RegisterRollupAdapter makes a RollupAdapter factory available under kind,
+// for selection via a TaskFetcher entry's Adapter field. It is meant to be
+// called from init() in the file that defines the adapter, the same way
+// database/sql drivers register themselves.
+func RegisterRollupAdapter(kind string, factory RollupAdapterFactory) {
+	rollupAdapterFactories[kind] = factory
+}
+
+// defaultRollupAdapterKind preserves the pre-adapter behavior of assuming
+// every TaskFetcher entry talks to a Scroll-style rollup.
+const defaultRollupAdapterKind = "scroll"
+
+func buildRollupAdapter(entry *TaskFetcherConfig) (RollupAdapter, error) {
+	kind := entry.Adapter
+	if kind == "" {
+		kind = defaultRollupAdapterKind
+	}
+	factory, ok := rollupAdapterFactories[kind]
+	if !ok {
+		return nil, logex.NewErrorf("unknown rollup adapter: %v", kind)
+	}
+	return factory(entry)
+}
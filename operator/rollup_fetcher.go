@@ -0,0 +1,96 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// rollupFetcher binds one RollupAdapter to its own LogTracer, offset file,
+// and worker pool. Keeping these per-adapter means one rollup's offset or
+// ordering never interferes with another's when an Operator follows several
+// rollups at once.
+type rollupFetcher struct {
+	operator *Operator
+	adapter  RollupAdapter
+
+	source *ethclient.Client
+	offset *os.File
+	tracer *LogTracer
+	pool   *taskPool
+}
+
+// callback func for task fetcher
+//
+// GetBlock is read by the LogTracer at startup to find where to resume
+// scanning. It's safe to read concurrently with persistOffset's writes
+// below: persistOffset is the only thing that ever writes this file.
+//
+// persistOffset stores the block of the last task the committer actually
+// acknowledged -- that block is fully done and must never be rescanned, or
+// the log(s) that produced it would be resubmitted to the aggregator. So
+// the stored value is exclusive: GetBlock hands the tracer one past it as
+// the next block to scan.
+func (f *rollupFetcher) GetBlock() (uint64, error) {
+	data := make([]byte, 16)
+	n, err := f.offset.ReadAt(data, 0)
+	if n == 0 {
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, logex.Trace(err, n)
+	}
+	data = bytes.Trim(data[:n], "\x00\r\n ")
+
+	number, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, logex.Trace(err)
+	}
+	return uint64(number) + 1, nil
+}
+
+// callback func for task fetcher
+//
+// SaveBlock is a no-op. The LogTracer calls it the instant OnNewLog
+// returns, but OnNewLog only enqueues the log onto the task pool and
+// returns immediately -- long before the log has actually been signed and
+// submitted to the aggregator. Persisting the offset here would advance it
+// past tasks that haven't been submitted yet, and race with the pool's own
+// writes. persistOffset, driven solely by the task pool's committer once a
+// task is actually acknowledged, is the only writer of the offset file.
+func (f *rollupFetcher) SaveBlock(offset uint64) error {
+	return nil
+}
+
+// persistOffset is the sole writer of the offset file. It is passed to
+// newTaskPool as the committer's saveBlock func, so the offset only ever
+// advances past a task once that task's aggregator.SubmitTask has actually
+// succeeded (or the pool is configured to skip failures).
+func (f *rollupFetcher) persistOffset(offset uint64) error {
+	data := []byte(strconv.FormatUint(offset, 10))
+	_, err := f.offset.WriteAt(data, 0)
+	return err
+}
+
+// callback func for task fetcher
+func (f *rollupFetcher) OnNewLog(ctx context.Context, log *types.Log) error {
+	f.pool.Submit(log)
+	return nil
+}
+
+func (f *rollupFetcher) processLog(ctx context.Context, log *types.Log) error {
+	return f.operator.processLog(ctx, f, log)
+}
+
+// Start launches this fetcher's worker pool and returns the channel its
+// committer reports its terminal error on. The LogTracer itself is run by
+// the caller, since LogTracer.Run blocks until ctx is cancelled.
+func (f *rollupFetcher) Start(ctx context.Context, workers int) <-chan error {
+	return f.pool.Start(ctx, workers)
+}
@@ -0,0 +1,71 @@
+package operator
+
+import (
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func init() {
+	RegisterRollupAdapter("optimism", newOptimismAdapter)
+}
+
+// optimismAdapter decodes the legacy CanonicalTransactionChain
+// appendSequencerBatch calldata paired with a SequencerBatchAppended event:
+// a 5-byte shouldStartAtElement, a 3-byte totalElementsToAppend, followed by
+// the batch contexts.
+//
+// The CTC's element index is a combined sequencer+queue position, not an L2
+// block height, so treating [shouldStartAtElement,
+// shouldStartAtElement+totalElementsToAppend) as a contiguous block range
+// (the way the Scroll adapter flattens chunks into one) is only correct
+// under Config.Simulation, where proverGetPoe uses StartBlock/EndBlock
+// solely to pick state roots off a local devnet that advances one block per
+// element. Decoding a real OP-stack batch into actual L2 block numbers
+// needs the L2 chain's queue-index -> block-number mapping, which this
+// adapter doesn't have and shouldn't attempt to fake outside simulation.
+type optimismAdapter struct {
+	identifier   int64
+	quorumNumber byte
+	addresses    []common.Address
+	topics       []common.Hash
+}
+
+func newOptimismAdapter(entry *TaskFetcherConfig) (RollupAdapter, error) {
+	return &optimismAdapter{
+		identifier:   entry.Identifier,
+		quorumNumber: entry.QuorumNumber,
+		addresses:    entry.Addresses,
+		topics:       entry.Topics,
+	}, nil
+}
+
+func (a *optimismAdapter) Identifier() int64           { return a.identifier }
+func (a *optimismAdapter) QuorumName() string          { return "Optimism SGX Quorum" }
+func (a *optimismAdapter) QuorumNumber() byte          { return a.quorumNumber }
+func (a *optimismAdapter) Topics() []common.Hash       { return a.topics }
+func (a *optimismAdapter) Addresses() []common.Address { return a.addresses }
+
+func (a *optimismAdapter) DecodeBatch(tx *types.Transaction, topics []common.Hash) ([]BlockRef, BatchMeta, error) {
+	data := tx.Data()[4:]
+	if len(data) < 8 {
+		return nil, BatchMeta{}, logex.NewErrorf("optimism batch calldata too short: %v bytes", len(data))
+	}
+
+	startElement := uint64(data[0])<<32 | uint64(data[1])<<24 | uint64(data[2])<<16 | uint64(data[3])<<8 | uint64(data[4])
+	totalElements := uint64(data[5])<<16 | uint64(data[6])<<8 | uint64(data[7])
+	if totalElements == 0 {
+		return nil, BatchMeta{}, nil
+	}
+
+	refs := make([]BlockRef, 0, totalElements)
+	for i := uint64(0); i < totalElements; i++ {
+		refs = append(refs, BlockRef{Number: startElement + i})
+	}
+
+	meta := BatchMeta{
+		StartBlock: refs[0].Number,
+		EndBlock:   refs[len(refs)-1].Number,
+	}
+	return refs, meta, nil
+}
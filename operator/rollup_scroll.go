@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func init() {
+	RegisterRollupAdapter("scroll", newScrollAdapter)
+}
+
+var scrollCommitBatchABI = func() abi.ABI {
+	ty := `[{"inputs":[{"internalType":"uint8","name":"_version","type":"uint8"},{"internalType":"bytes","name":"_parentBatchHeader","type":"bytes"},{"internalType":"bytes[]","name":"_chunks","type":"bytes[]"},{"internalType":"bytes","name":"_skippedL1MessageBitmap","type":"bytes"}],"name":"commitBatch","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	result, err := abi.JSON(bytes.NewReader([]byte(ty)))
+	if err != nil {
+		panic(err)
+	}
+	return result
+}()
+
+// scrollAdapter decodes Scroll's commitBatch(uint8,bytes,bytes[],bytes)
+// calldata, where each chunk packs a 60-byte block context per block.
+type scrollAdapter struct {
+	identifier   int64
+	quorumNumber byte
+	addresses    []common.Address
+	topics       []common.Hash
+}
+
+func newScrollAdapter(entry *TaskFetcherConfig) (RollupAdapter, error) {
+	return &scrollAdapter{
+		identifier:   entry.Identifier,
+		quorumNumber: entry.QuorumNumber,
+		addresses:    entry.Addresses,
+		topics:       entry.Topics,
+	}, nil
+}
+
+func (a *scrollAdapter) Identifier() int64           { return a.identifier }
+func (a *scrollAdapter) QuorumName() string          { return "Scroll SGX Quorum" }
+func (a *scrollAdapter) QuorumNumber() byte          { return a.quorumNumber }
+func (a *scrollAdapter) Topics() []common.Hash       { return a.topics }
+func (a *scrollAdapter) Addresses() []common.Address { return a.addresses }
+
+func (a *scrollAdapter) DecodeBatch(tx *types.Transaction, topics []common.Hash) ([]BlockRef, BatchMeta, error) {
+	args, err := scrollCommitBatchABI.Methods["commitBatch"].Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return nil, BatchMeta{}, err
+	}
+
+	// StartBlock/EndBlock are computed exactly as the pre-adapter code did:
+	// the first block seen becomes StartBlock, and EndBlock is only ever
+	// set by a later block. A batch that covers a single block therefore
+	// leaves EndBlock at its zero value rather than equal to StartBlock.
+	// That's a pre-existing quirk of this decode, not something this
+	// extraction should change.
+	var refs []BlockRef
+	var startBlock, endBlock uint64
+	for _, chunk := range args[2].([][]byte) {
+		for i := 0; i < int(chunk[0]); i++ {
+			blockNumber := binary.BigEndian.Uint64(chunk[1:][i*60 : i*60+8])
+			refs = append(refs, BlockRef{Number: blockNumber})
+			if startBlock == 0 {
+				startBlock = blockNumber
+			} else {
+				endBlock = blockNumber
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil, BatchMeta{}, nil
+	}
+
+	meta := BatchMeta{
+		StartBlock: startBlock,
+		EndBlock:   endBlock,
+	}
+	return refs, meta, nil
+}
@@ -0,0 +1,239 @@
+package operator
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chzyer/logex"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Bounded retry/backoff applied to a single task before it's ever handed to
+// skipOnError. A batch shouldn't be permanently dropped just because one
+// HeaderByNumber or SubmitTask call hit a transient RPC blip.
+const (
+	taskMaxAttempts   = 4
+	taskRetryBaseWait = 500 * time.Millisecond
+	taskRetryMaxWait  = 5 * time.Second
+)
+
+// seqHeap is a min-heap of pending task sequence numbers, used by taskPool's
+// committer to find the longest contiguous run of completed tasks even
+// though workers finish out of order.
+type seqHeap []uint64
+
+func (h seqHeap) Len() int            { return len(h) }
+func (h seqHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h seqHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *seqHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *seqHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+type logTask struct {
+	seq uint64
+	log *types.Log
+}
+
+type taskAck struct {
+	seq   uint64
+	block uint64
+	err   error
+}
+
+// taskPool fans out per-log processing across a bounded set of workers while
+// a single committer goroutine keeps offset persistence strictly ordered:
+// the on-disk offset only ever advances to the highest block whose task,
+// and every task before it, has been acknowledged, even though the workers
+// themselves may finish in any order. A restart therefore never re-submits
+// a task in that committed prefix; a task that completed while an earlier
+// one was still pending is not covered by the offset and is processed again.
+type taskPool struct {
+	process     func(ctx context.Context, log *types.Log) error
+	saveBlock   func(uint64) error
+	skipOnError bool
+	logger      *logex.Logger
+
+	// maxAttempts/retryBaseWait/retryMaxWait govern processWithRetry. They
+	// default to the task* constants above; tests shrink them to keep
+	// retry-path cases fast.
+	maxAttempts   int
+	retryBaseWait time.Duration
+	retryMaxWait  time.Duration
+
+	in   chan logTask
+	acks chan taskAck
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	nextSeq uint64
+}
+
+// newTaskPool builds a taskPool whose input and ack buffers are sized for
+// the given number of workers (clamped to at least 1). The worker goroutines
+// themselves are launched by Start, whose own workers argument sets how many
+// run.
+func newTaskPool(workers int, process func(ctx context.Context, log *types.Log) error, saveBlock func(uint64) error, skipOnError bool, logger *logex.Logger) *taskPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &taskPool{
+		process:       process,
+		saveBlock:     saveBlock,
+		skipOnError:   skipOnError,
+		logger:        logger,
+		maxAttempts:   taskMaxAttempts,
+		retryBaseWait: taskRetryBaseWait,
+		retryMaxWait:  taskRetryMaxWait,
+		in:            make(chan logTask, workers*2),
+		acks:          make(chan taskAck, workers*2),
+	}
+}
+
+// Submit enqueues log for processing, assigning it the next sequence number
+// in arrival order. Callers must submit in the same order the source
+// produces logs so the committer can rebuild that order from completions.
+func (p *taskPool) Submit(log *types.Log) {
+	p.mu.Lock()
+	seq := p.nextSeq
+	p.nextSeq++
+	p.mu.Unlock()
+	p.in <- logTask{seq: seq, log: log}
+}
+
+// Start launches the worker goroutines and the committer goroutine and
+// returns immediately. The returned channel receives the committer's
+// terminal error exactly once -- nil on clean shutdown (ctx cancelled or
+// acks closed), or the first hard task error when skipOnError is false --
+// so callers can surface it instead of it vanishing into a discarded
+// goroutine return value.
+func (p *taskPool) Start(ctx context.Context, workers int) <-chan error {
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.worker(ctx)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.commit(ctx)
+	}()
+	return errCh
+}
+
+func (p *taskPool) worker(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-p.in:
+			if !ok {
+				return
+			}
+			err := p.processWithRetry(ctx, task)
+			if err != nil {
+				p.logger.Errorf("task seq=%v block=%v failed after %v attempts: %v", task.seq, task.log.BlockNumber, p.maxAttempts, err)
+				if !p.skipOnError {
+					p.sendAck(ctx, taskAck{seq: task.seq, block: task.log.BlockNumber, err: err})
+					continue
+				}
+			}
+			p.sendAck(ctx, taskAck{seq: task.seq, block: task.log.BlockNumber})
+		}
+	}
+}
+
+// processWithRetry runs process up to p.maxAttempts times, backing off
+// between attempts, before handing the final error back to worker. This
+// keeps a transient RPC blip from being treated as a permanent task
+// failure -- skipOnError exists for genuinely bad tasks, not flaky nodes.
+func (p *taskPool) processWithRetry(ctx context.Context, task logTask) error {
+	wait := p.retryBaseWait
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		err = p.process(ctx, task.log)
+		if err == nil {
+			return nil
+		}
+		if attempt == p.maxAttempts {
+			break
+		}
+		p.logger.Errorf("task seq=%v block=%v attempt %v/%v failed, retrying in %v: %v", task.seq, task.log.BlockNumber, attempt, p.maxAttempts, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if wait *= 2; wait > p.retryMaxWait {
+			wait = p.retryMaxWait
+		}
+	}
+	return err
+}
+
+func (p *taskPool) sendAck(ctx context.Context, ack taskAck) {
+	select {
+	case p.acks <- ack:
+	case <-ctx.Done():
+	}
+}
+
+// commit drains acks and advances the offset as soon as a contiguous prefix
+// of submitted tasks has completed. When skipOnError is false, a failing
+// task's sequence number is simply never added to the contiguous run, which
+// permanently halts offset advancement at that task without the committer
+// itself returning -- it keeps draining acks so workers blocked in sendAck
+// are never stranded. The first such error is remembered and returned once
+// the pool actually shuts down (ctx cancelled or acks closed), so Start's
+// caller can still observe it via the channel Start returns.
+func (p *taskPool) commit(ctx context.Context) error {
+	pending := map[uint64]taskAck{}
+	h := &seqHeap{}
+	var nextCommit uint64
+	var lastBlock uint64
+	var haveLastBlock bool
+	var firstErr error
+
+	for {
+		select {
+		case <-ctx.Done():
+			return firstErr
+		case ack, ok := <-p.acks:
+			if !ok {
+				return firstErr
+			}
+			if ack.err != nil && !p.skipOnError {
+				if firstErr == nil {
+					firstErr = logex.Trace(ack.err)
+				}
+				continue
+			}
+
+			pending[ack.seq] = ack
+			heap.Push(h, ack.seq)
+
+			for h.Len() > 0 && (*h)[0] == nextCommit {
+				seq := heap.Pop(h).(uint64)
+				done := pending[seq]
+				delete(pending, seq)
+				lastBlock = done.block
+				haveLastBlock = true
+				nextCommit++
+			}
+
+			if haveLastBlock {
+				if err := p.saveBlock(lastBlock); err != nil {
+					p.logger.Error(logex.Trace(err))
+				}
+			}
+		}
+	}
+}